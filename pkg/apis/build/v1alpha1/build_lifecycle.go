@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+)
+
+// BuildSucceeded is the condition type that tracks whether a build's Pod
+// ran all of its steps to completion.
+const BuildSucceeded duckv1alpha1.ConditionType = "Succeeded"
+
+// BuildProvider identifies what back-end implemented a Build.
+type BuildProvider string
+
+// ClusterBuildProvider indicates the Build was executed as a Pod in the
+// same cluster as the Build resource itself.
+const ClusterBuildProvider BuildProvider = "Cluster"
+
+// ClusterSpec gives the status of a Build that ran as a Pod in-cluster.
+type ClusterSpec struct {
+	Namespace string `json:"namespace,omitempty"`
+	PodName   string `json:"podName,omitempty"`
+}
+
+// BuildStatus is the status of a Build, populated from the Pod that
+// implements it.
+type BuildStatus struct {
+	duckv1alpha1.Status `json:",inline"`
+
+	// Builder identifies the back-end that executed this Build.
+	Builder BuildProvider `json:"builder,omitempty"`
+
+	// Cluster provides additional information about the Pod, if Builder is
+	// ClusterBuildProvider.
+	Cluster *ClusterSpec `json:"cluster,omitempty"`
+
+	// StartTime is the time the build's Pod started running.
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// StepStates mirrors the ContainerState of each of the build's Steps,
+	// in the order their statuses appear in the Pod's ContainerStatuses.
+	StepStates []corev1.ContainerState `json:"stepStates,omitempty"`
+
+	// StepsCompleted names the steps whose containers have terminated,
+	// whether they succeeded or failed, in the order their statuses appear
+	// in the Pod's ContainerStatuses. A step that was skipped because an
+	// earlier step failed is not included.
+	StepsCompleted []string `json:"stepsCompleted,omitempty"`
+
+	// SidecarStates mirrors the ContainerState of each of the build's
+	// Sidecars.
+	SidecarStates []corev1.ContainerState `json:"sidecarStates,omitempty"`
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import corev1 "k8s.io/api/core/v1"
+
+// BuildStep runs a build's container, with any build-specific extensions
+// layered on top of the Container it wraps.
+type BuildStep struct {
+	corev1.Container `json:",inline"`
+
+	// Script, if specified, is a shell script materialized onto an
+	// emptyDir and run in place of the step's Command. It is mutually
+	// exclusive with Command; Args is ignored when Script is set, since
+	// the materialized script is invoked with no arguments.
+	Script string `json:"script,omitempty"`
+}
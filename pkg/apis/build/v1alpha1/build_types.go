@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains API Schema definitions for the build v1alpha1
+// API group.
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Build represents a build of a container image. A Build is created by
+// instantiating this CRD.
+type Build struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BuildSpec   `json:"spec,omitempty"`
+	Status BuildStatus `json:"status,omitempty"`
+}
+
+// BuildSpec describes the steps involved in producing the image(s) the
+// build is responsible for.
+type BuildSpec struct {
+	// ServiceAccountName is the name of the ServiceAccount whose secrets
+	// the build's credential-initializer should use. Defaults to "default".
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Source specifies the input to the build.
+	Source *SourceSpec `json:"source,omitempty"`
+
+	// Steps are the steps of the build; each is run in the order specified.
+	Steps []BuildStep `json:"steps,omitempty"`
+
+	// Sidecars run for the lifetime of the build's Pod, alongside Steps,
+	// rather than being chained into their execution order.
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// Volumes is a collection of volumes that are available to mount into
+	// the steps of the build.
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// NodeSelector is a selector which must be true for the Pod to fit on a node.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Affinity is the scheduling constraints on the Pod that runs the build.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+}
+
+// SourceSpec defines the input to the Build.
+type SourceSpec struct {
+	// Git fetches source from a git repository.
+	Git *GitSourceSpec `json:"git,omitempty"`
+	// GCS fetches source from a Google Cloud Storage location.
+	GCS *GCSSourceSpec `json:"gcs,omitempty"`
+	// Custom runs a custom container to fetch source.
+	Custom *corev1.Container `json:"custom,omitempty"`
+
+	// SubPath, if set, contains the subdirectory within the fetched source
+	// that the build's steps should use as their working directory.
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// GitSourceSpec describes a Git repository and revision to fetch.
+type GitSourceSpec struct {
+	Url      string `json:"url"`
+	Revision string `json:"revision"`
+}
+
+// GCSSourceType defines the type of GCS source fetch to perform.
+type GCSSourceType string
+
+const (
+	// GCSManifest indicates the source is a manifest listing objects to fetch.
+	GCSManifest GCSSourceType = "Manifest"
+	// GCSArchive indicates the source is a single archive to fetch and expand.
+	GCSArchive GCSSourceType = "Archive"
+)
+
+// GCSSourceSpec describes a Google Cloud Storage location to fetch source from.
+type GCSSourceSpec struct {
+	Type     GCSSourceType `json:"type,omitempty"`
+	Location string        `json:"location"`
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ValidateVolumes checks that a Pod's volumes don't declare the same name
+// more than once.
+func ValidateVolumes(volumes []corev1.Volume) error {
+	seen := map[string]bool{}
+	for _, v := range volumes {
+		if seen[v.Name] {
+			return fmt.Errorf("duplicate volume name %q", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
@@ -1,17 +1,34 @@
 package entrypoint
 
-type fakeWaiter struct{ waited bool }
+type fakeWaiter struct {
+	waited  string
+	content string
+	err     error
+}
 
-func (f *fakeWaiter) Wait(string) { f.waited = true }
+func (f *fakeWaiter) Wait(file string, expectContent bool) (string, error) {
+	f.waited = file
+	return f.content, f.err
+}
 
 type fakeRunner struct {
 	args []string
+	err  error
 }
 
-func (f *fakeRunner) Run(args ...string) {
+func (f *fakeRunner) Run(args ...string) error {
 	f.args = args
+	return f.err
 }
 
-type fakePostWriter struct{ wrote bool }
+type fakePostWriter struct {
+	wrote   bool
+	file    string
+	content string
+}
 
-func (f *fakePostWriter) Write(string) { f.wrote = true }
+func (f *fakePostWriter) Write(file, content string) {
+	f.wrote = true
+	f.file = file
+	f.content = content
+}
@@ -1,25 +1,63 @@
 package entrypoint
 
+import "errors"
+
+// SkipError is returned by Entrypointer.Go when a predecessor step's
+// post-file indicated failure, so this step's command was never run.
+// Callers should treat it like context.Canceled: it is not a runtime
+// failure of this step, just a short-circuit of the step chain.
+var SkipError = errors.New("entrypoint: skipping step, a previous step did not succeed")
+
+// SkippedMessagePrefix is prepended to the content written to a skipped
+// step's post-file (and, since that file doubles as the step's container
+// termination message, surfaced on its ContainerStatus), so that
+// StatusFromPod can tell a skipped step apart from one that actually ran
+// and failed.
+const SkippedMessagePrefix = "skipped: "
+
 type Entrypointer struct {
 	Entrypoint, WaitFile, PostFile string
 	Args                           []string
 
+	// WaitFileContent indicates that, once WaitFile exists, its content
+	// is read as well. An empty file means the previous step succeeded,
+	// so this step runs. Non-empty content signals that a previous step
+	// failed, so this step's command is skipped rather than run, and its
+	// own post-file carries that content onward, prefixed with
+	// SkippedMessagePrefix.
+	WaitFileContent bool
+
 	Runner     Runner
 	Waiter     Waiter
 	PostWriter PostWriter
 }
 
-func (e Entrypointer) Go() {
+// Go optionally waits for a previous step, runs the step's command, and
+// writes a post-file to unblock the step that follows. It returns
+// SkipError if this step was skipped because a previous one failed, or
+// the error from running the command, if any; main is responsible for
+// translating that into an exit code.
+func (e Entrypointer) Go() error {
 	if e.WaitFile != "" {
-		e.Waiter.Wait(e.WaitFile)
+		content, err := e.Waiter.Wait(e.WaitFile, e.WaitFileContent)
+		if err != nil {
+			return err
+		}
+		if e.WaitFileContent && content != "" {
+			e.PostWriter.Write(e.PostFile, SkippedMessagePrefix+content)
+			return SkipError
+		}
 	}
 
 	if e.Entrypoint != "" {
 		e.Args = append([]string{e.Entrypoint}, e.Args...)
 	}
-	e.Runner.Run(e.Args...)
 
-	if e.PostFile != "" {
-		e.PostWriter.Write(e.PostFile)
+	runErr := e.Runner.Run(e.Args...)
+	msg := ""
+	if runErr != nil {
+		msg = runErr.Error()
 	}
+	e.PostWriter.Write(e.PostFile, msg)
+	return runErr
 }
@@ -0,0 +1,79 @@
+package entrypoint
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntrypointerSkipsOnFailedPredecessor(t *testing.T) {
+	waiter := &fakeWaiter{content: "some previous step's failure reason"}
+	runner := &fakeRunner{}
+	writer := &fakePostWriter{}
+
+	e := Entrypointer{
+		Entrypoint:      "my-entrypoint",
+		WaitFile:        "/builder/downward/step-0",
+		WaitFileContent: true,
+		PostFile:        "/builder/downward/step-1",
+		Runner:          runner,
+		Waiter:          waiter,
+		PostWriter:      writer,
+	}
+
+	if err := e.Go(); err != SkipError {
+		t.Fatalf("Go() = %v, want SkipError", err)
+	}
+	if runner.args != nil {
+		t.Errorf("command was run, want it skipped; args = %v", runner.args)
+	}
+	if !writer.wrote {
+		t.Error("post-file was not written for skipped step")
+	}
+	if want := SkippedMessagePrefix + waiter.content; writer.content != want {
+		t.Errorf("post-file content = %q, want %q", writer.content, want)
+	}
+}
+
+func TestEntrypointerRunsWhenNoWaitFile(t *testing.T) {
+	runner := &fakeRunner{}
+	writer := &fakePostWriter{}
+
+	e := Entrypointer{
+		Entrypoint: "my-entrypoint",
+		Args:       []string{"arg"},
+		PostFile:   "/builder/downward/step-0",
+		Runner:     runner,
+		Waiter:     &fakeWaiter{},
+		PostWriter: writer,
+	}
+
+	if err := e.Go(); err != nil {
+		t.Fatalf("Go() = %v, want nil", err)
+	}
+	if got, want := runner.args, []string{"my-entrypoint", "arg"}; len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("Run args = %v, want %v", got, want)
+	}
+	if writer.content != "" {
+		t.Errorf("post-file content = %q, want empty for successful step", writer.content)
+	}
+}
+
+func TestEntrypointerPropagatesFailureReason(t *testing.T) {
+	runErr := errors.New("exit status 1")
+	runner := &fakeRunner{err: runErr}
+	writer := &fakePostWriter{}
+
+	e := Entrypointer{
+		PostFile:   "/builder/downward/step-0",
+		Runner:     runner,
+		Waiter:     &fakeWaiter{},
+		PostWriter: writer,
+	}
+
+	if err := e.Go(); err != runErr {
+		t.Fatalf("Go() = %v, want %v", err, runErr)
+	}
+	if writer.content != runErr.Error() {
+		t.Errorf("post-file content = %q, want %q", writer.content, runErr.Error())
+	}
+}
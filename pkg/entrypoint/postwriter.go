@@ -1,23 +1,32 @@
 package entrypoint
 
 import (
+	"io/ioutil"
 	"log"
 	"os"
 )
 
 type PostWriter interface {
-	Write(file string)
+	// Write creates file, if it doesn't already exist. If content is
+	// non-empty, it's written as the file's contents.
+	Write(file, content string)
 }
 
 type RealPostWriter struct{}
 
 var _ PostWriter = (*RealPostWriter)(nil)
 
-func (*RealPostWriter) Write(file string) {
+func (*RealPostWriter) Write(file, content string) {
 	if file == "" {
 		return
 	}
-	if _, err := os.Create(file); err != nil {
-		log.Fatalf("Creating %q: %v", file, err)
+	if content == "" {
+		if _, err := os.Create(file); err != nil {
+			log.Fatalf("Creating %q: %v", file, err)
+		}
+		return
+	}
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		log.Fatalf("Writing %q: %v", file, err)
 	}
 }
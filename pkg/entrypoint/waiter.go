@@ -1,28 +1,45 @@
 package entrypoint
 
 import (
-	"log"
+	"io/ioutil"
 	"os"
 	"time"
 )
 
+// waitPollingInterval is how often RealWaiter checks for the wait file.
+const waitPollingInterval = time.Second
+
 type Waiter interface {
-	Wait(file string)
+	// Wait blocks until file exists, then returns. If expectContent, the
+	// file's content is read and returned once it exists, whether or not
+	// that content is empty: an empty file means the predecessor step
+	// succeeded, while non-empty content carries its failure reason.
+	Wait(file string, expectContent bool) (string, error)
 }
 
-type RealWaiter struct{ waitFile string }
+type RealWaiter struct{}
 
 var _ Waiter = (*RealWaiter)(nil)
 
-func (*RealWaiter) Wait(file string) {
+func (*RealWaiter) Wait(file string, expectContent bool) (string, error) {
 	if file == "" {
-		return
+		return "", nil
 	}
-	for ; ; time.Sleep(time.Second) {
-		if _, err := os.Stat(file); err == nil {
-			return
-		} else if !os.IsNotExist(err) {
-			log.Fatalf("Waiting for %q: %v", file, err)
+	for ; ; time.Sleep(waitPollingInterval) {
+		if _, err := os.Stat(file); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
 		}
+		break
+	}
+	if !expectContent {
+		return "", nil
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
 	}
+	return string(b), nil
 }
@@ -0,0 +1,62 @@
+package entrypoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRealWaiterReturnsImmediatelyOnEmptyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "waiter")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "post")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	done := make(chan struct{})
+	var got string
+	go func() {
+		got, err = (&RealWaiter{}).Wait(file, true)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		if err != nil {
+			t.Fatalf("Wait() = %v", err)
+		}
+		if got != "" {
+			t.Errorf("Wait() content = %q, want empty string for a successful predecessor", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait() never returned for a pre-existing empty file; a successful multi-step build would hang here")
+	}
+}
+
+func TestRealWaiterReturnsContentOnFailedPredecessor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "waiter")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "post")
+	if err := ioutil.WriteFile(file, []byte("step failed"), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	got, err := (&RealWaiter{}).Wait(file, true)
+	if err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	if want := "step failed"; got != want {
+		t.Errorf("Wait() content = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package creds builds the init container that seeds a Build's
+// credentials before any build steps run.
+package creds
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	v1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+	"github.com/knative/build/pkg/credentials"
+	"github.com/knative/build/pkg/credentials/dockercreds"
+	"github.com/knative/build/pkg/credentials/gitcreds"
+	"github.com/knative/build/pkg/pod/kubeutil"
+)
+
+// Name is the unqualified name of the credential initialization container;
+// callers are expected to prefix it per their own init container naming
+// convention.
+const Name = "credential-initializer"
+
+// MakeInitializer returns the init container that seeds credentials for
+// the given Build's service account, plus any volumes it needs mounted to
+// access those credentials' secrets. Its calls to the apiserver are
+// retried with backoff on transient errors, since a single hiccup
+// shouldn't fail the whole build.
+func MakeInitializer(ctx context.Context, build *v1alpha1.Build, kubeclient kubernetes.Interface, image string, env []corev1.EnvVar, volumeMounts []corev1.VolumeMount, workingDir string, backoff kubeutil.Backoff) (*corev1.Container, []corev1.Volume, error) {
+	serviceAccountName := build.Spec.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = "default"
+	}
+
+	var sa *corev1.ServiceAccount
+	if err := kubeutil.RetryOnTransient(ctx, func() error {
+		var err error
+		sa, err = kubeclient.CoreV1().ServiceAccounts(build.Namespace).Get(serviceAccountName, metav1.GetOptions{})
+		return err
+	}, backoff); err != nil {
+		return nil, nil, err
+	}
+
+	builders := []credentials.Builder{dockercreds.NewBuilder(), gitcreds.NewBuilder()}
+
+	// Collect the volume declarations, there mounts into the cred-init container, and the arguments to it.
+	volumes := []corev1.Volume{}
+	args := []string{}
+	for _, secretEntry := range sa.Secrets {
+		var secret *corev1.Secret
+		err := kubeutil.RetryOnTransient(ctx, func() error {
+			var err error
+			secret, err = kubeclient.CoreV1().Secrets(build.Namespace).Get(secretEntry.Name, metav1.GetOptions{})
+			return err
+		}, backoff)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		matched := false
+		for _, b := range builders {
+			if sa := b.MatchingAnnotations(secret); len(sa) > 0 {
+				matched = true
+				args = append(args, sa...)
+			}
+		}
+
+		if matched {
+			name := fmt.Sprintf("secret-volume-%s", secret.Name)
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: credentials.VolumeName(secret.Name),
+			})
+			volumes = append(volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: secret.Name,
+					},
+				},
+			})
+		}
+	}
+
+	return &corev1.Container{
+		Name:         Name,
+		Image:        image,
+		Args:         args,
+		VolumeMounts: volumeMounts,
+		Env:          env,
+		WorkingDir:   workingDir,
+	}, volumes, nil
+}
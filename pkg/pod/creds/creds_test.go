@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package creds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	v1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+	"github.com/knative/build/pkg/pod/kubeutil"
+)
+
+func TestMakeInitializerRetriesTransientGetErrors(t *testing.T) {
+	build := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+	}
+	kubeclient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "ns"},
+	})
+
+	saGets := 0
+	kubeclient.PrependReactor("get", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		saGets++
+		if saGets < 3 {
+			return true, nil, kubeerrors.NewServerTimeout(schema.GroupResource{Resource: "serviceaccounts"}, "get", 0)
+		}
+		return false, nil, nil
+	})
+
+	backoff := kubeutil.Backoff{InitialDelay: time.Millisecond, Factor: 2, MaxDelay: 10 * time.Millisecond, MaxElapsed: time.Second}
+	cont, _, err := MakeInitializer(context.Background(), build, kubeclient, "creds-image", nil, nil, "/workspace", backoff)
+	if err != nil {
+		t.Fatalf("MakeInitializer() = %v", err)
+	}
+	if cont.Name != Name {
+		t.Errorf("container name = %q, want %q", cont.Name, Name)
+	}
+	if saGets != 3 {
+		t.Errorf("ServiceAccounts().Get calls = %d, want 3 (2 transient failures then success)", saGets)
+	}
+}
+
+func TestMakeInitializerDoesNotRetryNotFound(t *testing.T) {
+	build := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+	}
+	kubeclient := fake.NewSimpleClientset()
+
+	backoff := kubeutil.Backoff{InitialDelay: time.Millisecond, Factor: 2, MaxDelay: 10 * time.Millisecond, MaxElapsed: time.Second}
+	start := time.Now()
+	if _, _, err := MakeInitializer(context.Background(), build, kubeclient, "creds-image", nil, nil, "/workspace", backoff); err == nil {
+		t.Fatal("MakeInitializer() = nil error, want NotFound for a missing default ServiceAccount")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("elapsed = %v, want near-immediate failure with no retries for NotFound", elapsed)
+	}
+}
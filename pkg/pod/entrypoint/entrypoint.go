@@ -0,0 +1,165 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package entrypoint rewrites build step containers to invoke the
+// pkg/entrypoint binary, so that the kubelet can start every step
+// container together while the binary itself enforces serial execution.
+package entrypoint
+
+import (
+	"fmt"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MountName/MountPoint hold the entrypoint binary that each step
+	// container execs into.
+	MountName  = "tools"
+	MountPoint = "/builder/tools"
+	// BinaryPath is where the entrypoint binary is staged by
+	// PlaceToolsContainer, and where every wrapped step execs it from.
+	BinaryPath = MountPoint + "/entrypoint"
+
+	// DownwardMountName/DownwardMountPoint hold the wait/post files that
+	// steps use to signal completion to the step that follows them.
+	DownwardMountName  = "downward"
+	DownwardMountPoint = "/builder/downward"
+)
+
+var emptyVolumeSource = corev1.VolumeSource{
+	EmptyDir: &corev1.EmptyDirVolumeSource{},
+}
+
+// Volumes returns the volumes that must be added to a Pod that contains
+// any entrypoint-wrapped containers.
+func Volumes() []corev1.Volume {
+	return []corev1.Volume{
+		{Name: MountName, VolumeSource: emptyVolumeSource},
+		{Name: DownwardMountName, VolumeSource: emptyVolumeSource},
+	}
+}
+
+// PlaceToolsContainer returns the init container that stages a copy of
+// the entrypoint binary onto the tools volume, so that step containers
+// can exec into it without needing the binary baked into their own images.
+func PlaceToolsContainer(name, image string) corev1.Container {
+	return corev1.Container{
+		Name:    name,
+		Image:   image,
+		Command: []string{"cp", "/entrypoint", BinaryPath},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      MountName,
+			MountPath: MountPoint,
+		}},
+	}
+}
+
+// waitFile returns the downward file that step i must wait on before it
+// may run, or "" for the first step, which has no predecessor.
+func waitFile(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return filepath.Join(DownwardMountPoint, fmt.Sprintf("step-%d", i-1))
+}
+
+// postFile returns the downward file that step i writes once it has
+// completed, to unblock the step that follows it.
+func postFile(i int) string {
+	return filepath.Join(DownwardMountPoint, fmt.Sprintf("step-%d", i))
+}
+
+// OrderContainers rewrites each step's Command/Args to invoke the
+// entrypoint binary in place of the step's own command, chaining step i
+// on step i-1's post-file, and returns the resulting containers alongside
+// the volumes the Pod needs for the tools and downward mounts.
+//
+// A step whose predecessor failed is skipped rather than run: see
+// pkg/entrypoint's WaitFileContent behavior.
+func OrderContainers(steps []corev1.Container) ([]corev1.Container, []corev1.Volume, error) {
+	ordered := make([]corev1.Container, len(steps))
+	for i, step := range steps {
+		if len(step.Command) == 0 {
+			return nil, nil, newValidationError("MissingCommand",
+				"step %q must specify Command (or Script); Args alone relied on the image's own ENTRYPOINT, which the entrypoint wrapper has no way to resolve", step.Name)
+		}
+		ordered[i] = wrapInEntrypoint(step, i, true)
+	}
+	return ordered, Volumes(), nil
+}
+
+// WrapFinalStep wraps a synthetic step that's chained after numSteps
+// build steps, but — unlike OrderContainers' steps — must run regardless
+// of whether its predecessor failed (e.g. stop-sidecars cleanup).
+func WrapFinalStep(step corev1.Container, numSteps int) corev1.Container {
+	return wrapInEntrypoint(step, numSteps, false)
+}
+
+// wrapInEntrypoint rewrites step's Command/Args to invoke the entrypoint
+// binary. waitOnFailure controls whether this step is skipped when its
+// predecessor failed; it should be true for ordinary build steps, and
+// false for steps that must run regardless of earlier failures.
+func wrapInEntrypoint(step corev1.Container, i int, waitOnFailure bool) corev1.Container {
+	step.VolumeMounts = append(step.VolumeMounts, corev1.VolumeMount{
+		Name:      MountName,
+		MountPath: MountPoint,
+		ReadOnly:  true,
+	}, corev1.VolumeMount{
+		Name:      DownwardMountName,
+		MountPath: DownwardMountPoint,
+	})
+
+	stepEntrypoint := ""
+	args := step.Args
+	if len(step.Command) > 0 {
+		stepEntrypoint = step.Command[0]
+		args = append(append([]string{}, step.Command[1:]...), args...)
+	}
+
+	post := postFile(i)
+	entrypointArgs := []string{"-post_file", post, "-entrypoint", stepEntrypoint}
+	if wait := waitFile(i); wait != "" {
+		entrypointArgs = append(entrypointArgs, "-wait_file", wait)
+		if waitOnFailure {
+			entrypointArgs = append(entrypointArgs, "-wait_file_content")
+		}
+	}
+	entrypointArgs = append(entrypointArgs, "--")
+
+	step.Command = []string{BinaryPath}
+	step.Args = append(entrypointArgs, args...)
+	// The post-file doubles as this container's termination message, so
+	// that a skipped or failed step's reason is visible on its
+	// ContainerStatus without needing to read the downward volume directly.
+	step.TerminationMessagePath = post
+	return step
+}
+
+// validationError reports a step that can't be wrapped as given.
+type validationError struct {
+	Reason  string
+	Message string
+}
+
+func (ve *validationError) Error() string {
+	return fmt.Sprintf("%s: %s", ve.Reason, ve.Message)
+}
+
+func newValidationError(reason, format string, fmtArgs ...interface{}) error {
+	return &validationError{Reason: reason, Message: fmt.Sprintf(format, fmtArgs...)}
+}
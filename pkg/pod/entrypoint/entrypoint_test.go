@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestOrderContainersChaining(t *testing.T) {
+	steps := []corev1.Container{
+		{Name: "build-step-one", Command: []string{"cmd1"}, Args: []string{"arg1"}},
+		{Name: "build-step-two", Command: []string{"cmd2"}},
+		{Name: "build-step-three", Command: []string{"cmd3"}, Args: []string{"arg3"}},
+	}
+
+	wrapped, volumes, err := OrderContainers(steps)
+	if err != nil {
+		t.Fatalf("OrderContainers() = %v", err)
+	}
+	if got, want := len(volumes), 2; got != want {
+		t.Fatalf("len(volumes) = %d, want %d", got, want)
+	}
+
+	for i, w := range wrapped {
+		if got, want := w.Command, []string{BinaryPath}; !reflect.DeepEqual(got, want) {
+			t.Errorf("step %d Command = %v, want %v", i, got, want)
+		}
+	}
+
+	// Step 0 has no predecessor, so it shouldn't wait on anything.
+	if argsContain(wrapped[0].Args, "-wait_file") {
+		t.Errorf("step 0 Args = %v, want no -wait_file", wrapped[0].Args)
+	}
+
+	// Every subsequent step's -wait_file must match its predecessor's -post_file.
+	for i := 1; i < len(wrapped); i++ {
+		prevPost := argAfter(wrapped[i-1].Args, "-post_file")
+		wait := argAfter(wrapped[i].Args, "-wait_file")
+		if prevPost == "" || wait != prevPost {
+			t.Errorf("step %d -wait_file = %q, want predecessor's -post_file %q", i, wait, prevPost)
+		}
+		if !argsContain(wrapped[i].Args, "-wait_file_content") {
+			t.Errorf("step %d Args = %v, want -wait_file_content so failures short-circuit", i, wrapped[i].Args)
+		}
+	}
+
+	// The last step's -post_file should be the final link in the chain.
+	last := argAfter(wrapped[len(wrapped)-1].Args, "-post_file")
+	if want := postFile(len(wrapped) - 1); last != want {
+		t.Errorf("last step -post_file = %q, want %q", last, want)
+	}
+
+	// The original command should be passed as -entrypoint, and its args
+	// preserved after the "--" separator.
+	if got, want := append([]string{argAfter(wrapped[0].Args, "-entrypoint")}, argsAfterSeparator(wrapped[0].Args)...), []string{"cmd1", "arg1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("step 0 original command/args = %v, want %v", got, want)
+	}
+	if got, want := append([]string{argAfter(wrapped[2].Args, "-entrypoint")}, argsAfterSeparator(wrapped[2].Args)...), []string{"cmd3", "arg3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("step 2 original command/args = %v, want %v", got, want)
+	}
+}
+
+func TestOrderContainersRejectsStepWithoutCommand(t *testing.T) {
+	steps := []corev1.Container{
+		{Name: "build-step-one", Command: []string{"cmd1"}},
+		{Name: "build-step-two", Args: []string{"arg2"}},
+	}
+
+	if _, _, err := OrderContainers(steps); err == nil {
+		t.Fatal("OrderContainers() = nil error, want an error for a step with Args but no Command, since it can't be wrapped without losing the image's default ENTRYPOINT")
+	}
+}
+
+func TestWrapFinalStepAlwaysRuns(t *testing.T) {
+	stop := corev1.Container{Name: "build-step-stop-sidecars", Command: []string{"kubectl"}}
+	wrapped := WrapFinalStep(stop, 2)
+
+	if !argsContain(wrapped.Args, "-wait_file") {
+		t.Errorf("Args = %v, want -wait_file present", wrapped.Args)
+	}
+	if argsContain(wrapped.Args, "-wait_file_content") {
+		t.Errorf("Args = %v, want no -wait_file_content, since this step must run regardless of predecessor failure", wrapped.Args)
+	}
+}
+
+func argAfter(args []string, flag string) string {
+	for i, a := range args {
+		if a == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func argsContain(args []string, flag string) bool {
+	for _, a := range args {
+		if a == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func argsAfterSeparator(args []string) []string {
+	for i, a := range args {
+		if a == "--" {
+			return args[i+1:]
+		}
+	}
+	return nil
+}
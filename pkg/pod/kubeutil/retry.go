@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeutil holds small helpers for talking to the Kubernetes API
+// server that don't belong to any single pod-building package.
+package kubeutil
+
+import (
+	"context"
+	"net"
+	"time"
+
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Backoff configures RetryOnTransient's exponential backoff.
+type Backoff struct {
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+	// Factor is the multiplier applied to the delay after each retry.
+	Factor float64
+	// MaxDelay caps the delay between any two retries.
+	MaxDelay time.Duration
+	// MaxElapsed caps the total time spent retrying, across all attempts.
+	MaxElapsed time.Duration
+}
+
+// DefaultBackoff holds this package's default backoff settings. Callers
+// pass it, or their own Backoff, to RetryOnTransient explicitly; a zero
+// Backoff is not replaced by it.
+var DefaultBackoff = Backoff{
+	InitialDelay: 100 * time.Millisecond,
+	Factor:       2,
+	MaxDelay:     5 * time.Second,
+	MaxElapsed:   30 * time.Second,
+}
+
+// RetryOnTransient calls fn, retrying with exponential backoff as long as
+// fn returns a transient error: one of kubeerrors.IsServerTimeout,
+// IsTooManyRequests, IsInternalError, or a net.Error. Any other error,
+// including IsNotFound and IsForbidden, is returned immediately without
+// retrying. Retrying stops once backoff.MaxElapsed has passed or ctx is
+// done, whichever comes first, at which point the most recent error is
+// returned.
+func RetryOnTransient(ctx context.Context, fn func() error, backoff Backoff) error {
+	delay := backoff.InitialDelay
+	start := time.Now()
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !isTransient(err) {
+			return err
+		}
+		if time.Since(start)+delay > backoff.MaxElapsed {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		delay = time.Duration(float64(delay) * backoff.Factor)
+		if delay > backoff.MaxDelay {
+			delay = backoff.MaxDelay
+		}
+	}
+}
+
+func isTransient(err error) bool {
+	if kubeerrors.IsServerTimeout(err) || kubeerrors.IsTooManyRequests(err) || kubeerrors.IsInternalError(err) {
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
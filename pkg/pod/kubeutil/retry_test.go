@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRetryOnTransientRetriesThenSucceeds(t *testing.T) {
+	backoff := Backoff{InitialDelay: time.Millisecond, Factor: 2, MaxDelay: 10 * time.Millisecond, MaxElapsed: time.Second}
+
+	calls := 0
+	err := RetryOnTransient(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return kubeerrors.NewServerTimeout(schema.GroupResource{Resource: "secrets"}, "get", 0)
+		}
+		return nil
+	}, backoff)
+	if err != nil {
+		t.Fatalf("RetryOnTransient() = %v, want nil after eventual success", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetryOnTransientDoesNotRetryNotFound(t *testing.T) {
+	backoff := Backoff{InitialDelay: time.Millisecond, Factor: 2, MaxDelay: 10 * time.Millisecond, MaxElapsed: time.Second}
+
+	calls := 0
+	notFound := kubeerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "my-secret")
+	err := RetryOnTransient(context.Background(), func() error {
+		calls++
+		return notFound
+	}, backoff)
+	if err != notFound {
+		t.Fatalf("RetryOnTransient() = %v, want %v", err, notFound)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (no retries for a non-transient error)", calls)
+	}
+}
+
+func TestRetryOnTransientBoundsTotalElapsedTime(t *testing.T) {
+	backoff := Backoff{InitialDelay: time.Millisecond, Factor: 2, MaxDelay: 5 * time.Millisecond, MaxElapsed: 50 * time.Millisecond}
+
+	start := time.Now()
+	calls := 0
+	err := RetryOnTransient(context.Background(), func() error {
+		calls++
+		return kubeerrors.NewTooManyRequests("too many requests", 0)
+	}, backoff)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("RetryOnTransient() = nil, want the last transient error once MaxElapsed is exceeded")
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("elapsed = %v, want roughly bounded by MaxElapsed (%v)", elapsed, backoff.MaxElapsed)
+	}
+	if calls < 2 {
+		t.Errorf("calls = %d, want at least 2 attempts before giving up", calls)
+	}
+}
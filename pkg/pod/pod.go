@@ -0,0 +1,514 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pod provides methods to convert a Build CRD to a k8s Pod
+// resource.
+package pod
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	v1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+	"github.com/knative/build/pkg/pod/creds"
+	"github.com/knative/build/pkg/pod/entrypoint"
+	"github.com/knative/build/pkg/pod/kubeutil"
+	"github.com/knative/build/pkg/pod/sources"
+)
+
+const workspaceDir = "/workspace"
+
+// These are effectively const, but Go doesn't have such an annotation.
+var (
+	emptyVolumeSource = corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}
+	// These are injected into all of the source/step containers.
+	implicitEnvVars = []corev1.EnvVar{{
+		Name:  "HOME",
+		Value: "/builder/home",
+	}}
+	implicitVolumeMounts = []corev1.VolumeMount{{
+		Name:      "workspace",
+		MountPath: workspaceDir,
+	}, {
+		Name:      "home",
+		MountPath: "/builder/home",
+	}}
+	implicitVolumes = []corev1.Volume{{
+		Name:         "workspace",
+		VolumeSource: emptyVolumeSource,
+	}, {
+		Name:         "home",
+		VolumeSource: emptyVolumeSource,
+	}}
+)
+
+const (
+	// Prefixes to add to the name of the init containers.
+	// IMPORTANT: Changing these values without changing fluentd collection configuration
+	// will break log collection for init containers.
+	initContainerPrefix        = "build-step-"
+	unnamedInitContainerPrefix = "build-step-unnamed-"
+	// A label with the following is added to the pod to identify the pods belonging to a build.
+	buildNameLabelKey = "build.knative.dev/buildName"
+	// Name of the synthetic step, chained after the build's last real
+	// step, that stops any sidecars once the build's steps have finished.
+	stopSidecars = "stop-sidecars"
+	// Name of the init container that materializes Script steps as
+	// executable files onto the scripts volume.
+	placeScripts = "place-scripts"
+
+	// scriptsMountName/scriptsMountPoint hold the materialized contents
+	// of any step's Script field.
+	scriptsMountName  = "scripts"
+	scriptsMountPoint = "/builder/scripts"
+)
+
+var (
+	// The container used to initialize credentials before the build runs.
+	credsImage = flag.String("creds-image", "override-with-creds:latest",
+		"The container image for preparing our Build's credentials.")
+	// The container with Git that we use to implement the Git source step.
+	gitImage = flag.String("git-image", "override-with-git:latest",
+		"The container image containing our Git binary.")
+	// The image createStopSidecarsStep patches each sidecar's image to,
+	// once the build's steps have finished, so the kubelet restarts it
+	// into a quick exit.
+	nopImage = flag.String("nop-image", "override-with-nop:latest",
+		"The container image used to patch a build's sidecar containers so they exit once the build's steps have finished.")
+	gcsFetcherImage = flag.String("gcs-fetcher-image", "gcr.io/cloud-builders/gcs-fetcher:latest",
+		"The container image containing our GCS fetcher binary.")
+	// The container we use to place the entrypoint binary that wraps each step.
+	entrypointImage = flag.String("entrypoint-image", "override-with-entrypoint:latest",
+		"The container image containing our entrypoint binary.")
+	// The container with kubectl that we use to stop sidecars once the
+	// build's steps have finished.
+	kubectlImage = flag.String("kubectl-image", "override-with-kubectl:latest",
+		"The container image containing our kubectl binary, used to stop sidecars.")
+	// The container we use to materialize Script steps onto the scripts volume.
+	shellImage = flag.String("shell-image", "override-with-shell:latest",
+		"The container image containing a shell, used to place Script steps onto the scripts volume.")
+
+	// Backoff parameters for retrying transient apiserver errors, shared by
+	// every apiserver call this package makes on a Build's behalf: looking
+	// up the credential-initializer's ServiceAccount and Secrets, and
+	// creating the build's Pod.
+	credsRetryInitialDelay = flag.Duration("creds-retry-initial-delay", 100*time.Millisecond,
+		"The initial delay before retrying a transient apiserver error while preparing or creating a build's Pod.")
+	credsRetryFactor = flag.Float64("creds-retry-factor", 2,
+		"The multiplier applied to the retry delay after each transient apiserver error while preparing or creating a build's Pod.")
+	credsRetryMaxDelay = flag.Duration("creds-retry-max-delay", 5*time.Second,
+		"The maximum delay between retries of a transient apiserver error while preparing or creating a build's Pod.")
+	credsRetryMaxElapsed = flag.Duration("creds-retry-max-elapsed", 30*time.Second,
+		"The maximum total time to spend retrying transient apiserver errors while preparing or creating a build's Pod.")
+)
+
+// apiserverRetryBackoff returns the shared backoff settings, as configured
+// by flags, for every apiserver call this package retries.
+func apiserverRetryBackoff() kubeutil.Backoff {
+	return kubeutil.Backoff{
+		InitialDelay: *credsRetryInitialDelay,
+		Factor:       *credsRetryFactor,
+		MaxDelay:     *credsRetryMaxDelay,
+		MaxElapsed:   *credsRetryMaxElapsed,
+	}
+}
+
+// addImplicits injects the implicit env vars and volume mounts shared by
+// both build steps and sidecars, honoring the build source's subPath (if
+// any) for the workspace mount.
+func addImplicits(c corev1.Container, workspaceSubPath string) corev1.Container {
+	c.Env = append(implicitEnvVars, c.Env...)
+
+	requestedVolumeMounts := map[string]bool{}
+	for _, vm := range c.VolumeMounts {
+		requestedVolumeMounts[filepath.Clean(vm.MountPath)] = true
+	}
+	for _, imp := range implicitVolumeMounts {
+		if requestedVolumeMounts[filepath.Clean(imp.MountPath)] {
+			continue
+		}
+		// If the build's source specifies a subpath, use that in the
+		// implicit workspace volume mount.
+		if workspaceSubPath != "" && imp.Name == "workspace" {
+			imp.SubPath = workspaceSubPath
+		}
+		c.VolumeMounts = append(c.VolumeMounts, imp)
+	}
+
+	if c.WorkingDir == "" {
+		c.WorkingDir = workspaceDir
+	}
+	return c
+}
+
+// createStopSidecarsStep returns a synthetic step, chained after the
+// build's last real step, that patches every sidecar container's image to
+// the nop image. Kubernetes has no first-class way to stop a sidecar once
+// a Pod's other containers have finished, so instead we lean on the fact
+// that rewriting a running container's image causes the kubelet to
+// restart it; restarting into the nop image makes it exit right away.
+func createStopSidecarsStep(sidecars []corev1.Container) (corev1.Container, error) {
+	type containerPatch struct {
+		Name  string `json:"name"`
+		Image string `json:"image"`
+	}
+	patch := struct {
+		Spec struct {
+			Containers []containerPatch `json:"containers"`
+		} `json:"spec"`
+	}{}
+	for _, s := range sidecars {
+		patch.Spec.Containers = append(patch.Spec.Containers, containerPatch{Name: s.Name, Image: *nopImage})
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return corev1.Container{}, err
+	}
+
+	return corev1.Container{
+		Name:    initContainerPrefix + stopSidecars,
+		Image:   *kubectlImage,
+		Command: []string{"kubectl", "patch", "pod", "$(POD_NAME)", "--namespace", "$(POD_NAMESPACE)", "--type", "strategic", "--patch", string(b)},
+		Env: []corev1.EnvVar{{
+			Name:      "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		}, {
+			Name:      "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+		}},
+	}, nil
+}
+
+// scriptPath returns the path that step i's materialized script is
+// written to, under scriptsMountPoint.
+func scriptPath(i int, suffix string) string {
+	return filepath.Join(scriptsMountPoint, fmt.Sprintf("script-%d-%s", i, suffix))
+}
+
+// randomScriptSuffix returns a short random hex string, used to avoid any
+// possibility of collision between concurrently materialized scripts.
+func randomScriptSuffix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		// Extremely unlikely, and the suffix only exists to avoid
+		// collisions, not to provide any security property.
+		return "0"
+	}
+	return hex.EncodeToString(b)
+}
+
+// convertScripts rewrites any step with a non-empty Script into a step
+// whose Command execs the materialized script file, and returns the
+// place-scripts init container that writes those files onto a shared
+// emptyDir ahead of time. It returns a nil init container if no step uses
+// Script.
+func convertScripts(buildSteps []v1alpha1.BuildStep) ([]corev1.Container, *corev1.Container, error) {
+	var scripts, paths []string
+	steps := make([]corev1.Container, len(buildSteps))
+	for i, bStep := range buildSteps {
+		step := bStep.Container
+		if bStep.Script != "" {
+			if len(step.Command) > 0 {
+				return nil, nil, newValidationError("ScriptAndCommand",
+					"step %q specifies both Script and Command; only one is allowed", step.Name)
+			}
+
+			script := bStep.Script
+			if !strings.HasPrefix(script, "#!") {
+				script = "#!/bin/sh\nset -xe\n" + script
+			}
+
+			path := scriptPath(i, randomScriptSuffix())
+			scripts = append(scripts, script)
+			paths = append(paths, path)
+
+			step.Command = []string{path}
+			step.Args = nil
+			step.VolumeMounts = append(step.VolumeMounts, corev1.VolumeMount{
+				Name:      scriptsMountName,
+				MountPath: scriptsMountPoint,
+			})
+		}
+		steps[i] = step
+	}
+
+	if len(scripts) == 0 {
+		return steps, nil, nil
+	}
+	return steps, createPlaceScriptsInitContainer(paths, scripts), nil
+}
+
+// createPlaceScriptsInitContainer returns an init container that writes
+// each of scripts to its corresponding path in paths, and makes it
+// executable.
+func createPlaceScriptsInitContainer(paths, scripts []string) *corev1.Container {
+	var b strings.Builder
+	for i, script := range scripts {
+		fmt.Fprintf(&b, "cat > %s << 'BUILD_SCRIPT_EOF'\n%s\nBUILD_SCRIPT_EOF\nchmod +x %s\n", paths[i], script, paths[i])
+	}
+
+	return &corev1.Container{
+		Name:    initContainerPrefix + placeScripts,
+		Image:   *shellImage,
+		Command: []string{"sh", "-c", b.String()},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      scriptsMountName,
+			MountPath: scriptsMountPoint,
+		}},
+	}
+}
+
+// FromCRD converts a Build object to a Pod which implements the build specified
+// by the supplied CRD.
+func FromCRD(ctx context.Context, build *v1alpha1.Build, kubeclient kubernetes.Interface) (*corev1.Pod, error) {
+	build = build.DeepCopy()
+
+	cred, secretVolumes, err := creds.MakeInitializer(ctx, build, kubeclient, *credsImage, implicitEnvVars, implicitVolumeMounts, workspaceDir, apiserverRetryBackoff())
+	if err != nil {
+		return nil, err
+	}
+	cred.Name = initContainerPrefix + cred.Name
+
+	initContainers := []corev1.Container{*cred}
+	workspaceSubPath := ""
+	if source := build.Spec.Source; source != nil {
+		switch {
+		case source.Git != nil:
+			git, err := sources.GitToContainer(source.Git, *gitImage, workspaceDir, implicitEnvVars, implicitVolumeMounts)
+			if err != nil {
+				return nil, err
+			}
+			git.Name = initContainerPrefix + git.Name
+			initContainers = append(initContainers, *git)
+		case source.GCS != nil:
+			gcs, err := sources.GCSToContainer(source.GCS, *gcsFetcherImage, workspaceDir, implicitEnvVars, implicitVolumeMounts)
+			if err != nil {
+				return nil, err
+			}
+			gcs.Name = initContainerPrefix + gcs.Name
+			initContainers = append(initContainers, *gcs)
+		case source.Custom != nil:
+			cust, err := sources.CustomToContainer(source.Custom)
+			if err != nil {
+				return nil, err
+			}
+			// Prepend the custom container to the steps, to be
+			// augmented later with env, volume mounts, etc.
+			build.Spec.Steps = append([]v1alpha1.BuildStep{{Container: *cust}}, build.Spec.Steps...)
+		}
+
+		workspaceSubPath = build.Spec.Source.SubPath
+	}
+
+	steps, placeScriptsContainer, err := convertScripts(build.Spec.Steps)
+	if err != nil {
+		return nil, err
+	}
+	if placeScriptsContainer != nil {
+		initContainers = append(initContainers, *placeScriptsContainer)
+	}
+
+	for i, step := range steps {
+		// TODO(mattmoor): Check that volumeMounts match volumes.
+		step = addImplicits(step, workspaceSubPath)
+
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("%v%d", unnamedInitContainerPrefix, i)
+		} else {
+			step.Name = fmt.Sprintf("%v%v", initContainerPrefix, step.Name)
+		}
+		steps[i] = step
+	}
+
+	containers, entrypointVolumes, err := entrypoint.OrderContainers(steps)
+	if err != nil {
+		return nil, err
+	}
+	initContainers = append(initContainers, entrypoint.PlaceToolsContainer(initContainerPrefix+"place-tools", *entrypointImage))
+
+	for _, sidecar := range build.Spec.Sidecars {
+		if strings.HasPrefix(sidecar.Name, initContainerPrefix) {
+			return nil, newValidationError("SidecarNameCollision",
+				"sidecar name %q collides with the build step naming convention", sidecar.Name)
+		}
+		// Sidecars run for the lifetime of the Pod, alongside the
+		// entrypoint-serialized steps, rather than being chained into them.
+		containers = append(containers, addImplicits(sidecar, workspaceSubPath))
+	}
+	if len(build.Spec.Sidecars) > 0 {
+		stop, err := createStopSidecarsStep(build.Spec.Sidecars)
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, entrypoint.WrapFinalStep(stop, len(build.Spec.Steps)))
+	}
+
+	// Add our implicit volumes and any volumes needed for secrets to the explicitly
+	// declared user volumes.
+	volumes := append(build.Spec.Volumes, implicitVolumes...)
+	volumes = append(volumes, secretVolumes...)
+	volumes = append(volumes, entrypointVolumes...)
+	if placeScriptsContainer != nil {
+		volumes = append(volumes, corev1.Volume{Name: scriptsMountName, VolumeSource: emptyVolumeSource})
+	}
+	if err := v1alpha1.ValidateVolumes(volumes); err != nil {
+		return nil, err
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			// We execute the build's pod in the same namespace as where the build was
+			// created so that it can access colocated resources.
+			Namespace: build.Namespace,
+			Name:      fmt.Sprintf("pod-for-%s", build.Name), // TODO: Use GenerateName.
+			// Ensure our Pod gets a unique name.
+			//GenerateName: fmt.Sprintf("%s-", build.Name),
+			// If our parent Build is deleted, then we should be as well.
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(build, schema.GroupVersionKind{
+					Group:   v1alpha1.SchemeGroupVersion.Group,
+					Version: v1alpha1.SchemeGroupVersion.Version,
+					Kind:    "Build",
+				}),
+			},
+			Annotations: map[string]string{
+				"sidecar.istio.io/inject": "false",
+			},
+			Labels: map[string]string{
+				buildNameLabelKey: build.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			// If the build fails, don't restart it.
+			RestartPolicy:      corev1.RestartPolicyNever,
+			InitContainers:     initContainers,
+			Containers:         containers,
+			ServiceAccountName: build.Spec.ServiceAccountName,
+			Volumes:            volumes,
+			NodeSelector:       build.Spec.NodeSelector,
+			Affinity:           build.Spec.Affinity,
+		},
+	}, nil
+}
+
+// CreatePod creates the given Pod, retrying transient apiserver errors with
+// the same backoff FromCRD uses for its own calls, so that a hiccup while
+// creating the build's Pod doesn't fail the whole build.
+func CreatePod(ctx context.Context, pod *corev1.Pod, kubeclient kubernetes.Interface) (*corev1.Pod, error) {
+	var created *corev1.Pod
+	if err := kubeutil.RetryOnTransient(ctx, func() error {
+		var err error
+		created, err = kubeclient.CoreV1().Pods(pod.Namespace).Create(pod)
+		return err
+	}, apiserverRetryBackoff()); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func isImplicitEnvVar(ev corev1.EnvVar) bool {
+	for _, iev := range implicitEnvVars {
+		if ev.Name == iev.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func filterImplicitEnvVars(evs []corev1.EnvVar) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	for _, ev := range evs {
+		if isImplicitEnvVar(ev) {
+			continue
+		}
+		envs = append(envs, ev)
+	}
+	return envs
+}
+
+func isImplicitVolumeMount(vm corev1.VolumeMount) bool {
+	for _, ivm := range implicitVolumeMounts {
+		if vm.Name == ivm.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func filterImplicitVolumeMounts(vms []corev1.VolumeMount) []corev1.VolumeMount {
+	var volumes []corev1.VolumeMount
+	for _, vm := range vms {
+		if isImplicitVolumeMount(vm) {
+			continue
+		}
+		volumes = append(volumes, vm)
+	}
+	return volumes
+}
+
+func isImplicitVolume(v corev1.Volume) bool {
+	for _, iv := range implicitVolumes {
+		if v.Name == iv.Name {
+			return true
+		}
+	}
+	if strings.HasPrefix(v.Name, "secret-volume-") {
+		return true
+	}
+	return false
+}
+
+func filterImplicitVolumes(vs []corev1.Volume) []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, v := range vs {
+		if isImplicitVolume(v) {
+			continue
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes
+}
+
+type validationError struct {
+	Reason  string
+	Message string
+}
+
+func (ve *validationError) Error() string {
+	return fmt.Sprintf("%s: %s", ve.Reason, ve.Message)
+}
+
+// newValidationError returns a new validation error.
+func newValidationError(reason, format string, fmtArgs ...interface{}) error {
+	return &validationError{
+		Reason:  reason,
+		Message: fmt.Sprintf(format, fmtArgs...),
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	v1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+)
+
+func TestCreateStopSidecarsStepPatchesEverySidecar(t *testing.T) {
+	sidecars := []corev1.Container{{Name: "logger"}, {Name: "proxy"}}
+	step, err := createStopSidecarsStep(sidecars)
+	if err != nil {
+		t.Fatalf("createStopSidecarsStep() = %v", err)
+	}
+	patch := step.Command[len(step.Command)-1]
+	for _, s := range sidecars {
+		if !strings.Contains(patch, s.Name) {
+			t.Errorf("patch %q doesn't mention sidecar %q", patch, s.Name)
+		}
+	}
+	if !strings.Contains(patch, *nopImage) {
+		t.Errorf("patch %q doesn't reference the nop image %q", patch, *nopImage)
+	}
+}
+
+func TestCreatePodRetriesTransientCreateErrors(t *testing.T) {
+	kubeclient := fake.NewSimpleClientset()
+
+	creates := 0
+	kubeclient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		creates++
+		if creates < 3 {
+			return true, nil, kubeerrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "create", 0)
+		}
+		return false, nil, nil
+	})
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-for-build", Namespace: "ns"}}
+	created, err := CreatePod(context.Background(), pod, kubeclient)
+	if err != nil {
+		t.Fatalf("CreatePod() = %v", err)
+	}
+	if created.Name != pod.Name {
+		t.Errorf("created.Name = %q, want %q", created.Name, pod.Name)
+	}
+	if creates != 3 {
+		t.Errorf("Pods().Create calls = %d, want 3 (2 transient failures then success)", creates)
+	}
+}
+
+func TestFromCRDRejectsCollidingSidecarName(t *testing.T) {
+	build := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-build", Namespace: "ns"},
+		Spec: v1alpha1.BuildSpec{
+			Steps:    []v1alpha1.BuildStep{{Container: corev1.Container{Name: "one", Command: []string{"cmd"}}}},
+			Sidecars: []corev1.Container{{Name: initContainerPrefix + "evil"}},
+		},
+	}
+	kubeclient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "ns"},
+	})
+
+	if _, err := FromCRD(context.Background(), build, kubeclient); err == nil {
+		t.Fatal("FromCRD() = nil error, want an error for a sidecar name colliding with the build step naming convention")
+	}
+}
+
+func TestConvertScriptsNoop(t *testing.T) {
+	steps := []v1alpha1.BuildStep{{Container: corev1.Container{Name: "one", Command: []string{"cmd"}}}}
+	out, place, err := convertScripts(steps)
+	if err != nil {
+		t.Fatalf("convertScripts() = %v", err)
+	}
+	if place != nil {
+		t.Errorf("place-scripts container = %v, want nil when no step uses Script", place)
+	}
+	if got, want := out[0].Command, []string{"cmd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Command = %v, want untouched %v", got, want)
+	}
+}
+
+func TestConvertScriptsShebangDetection(t *testing.T) {
+	steps := []v1alpha1.BuildStep{
+		{Container: corev1.Container{Name: "no-shebang"}, Script: "echo hello"},
+		{Container: corev1.Container{Name: "has-shebang"}, Script: "#!/usr/bin/env python\nprint('hi')"},
+	}
+	out, place, err := convertScripts(steps)
+	if err != nil {
+		t.Fatalf("convertScripts() = %v", err)
+	}
+	if place == nil {
+		t.Fatalf("place-scripts container = nil, want non-nil")
+	}
+
+	script := place.Command[len(place.Command)-1]
+	if !strings.Contains(script, "#!/bin/sh\nset -xe\necho hello") {
+		t.Errorf("script content = %q, want default shebang prepended to a Script lacking one", script)
+	}
+	if !strings.Contains(script, "#!/usr/bin/env python\nprint('hi')") {
+		t.Errorf("script content = %q, want shebang preserved verbatim", script)
+	}
+
+	for i, step := range out {
+		if len(step.Command) != 1 || !strings.HasPrefix(step.Command[0], scriptsMountPoint) {
+			t.Errorf("step %d Command = %v, want a single path under %s", i, step.Command, scriptsMountPoint)
+		}
+		if step.Args != nil {
+			t.Errorf("step %d Args = %v, want nil", i, step.Args)
+		}
+	}
+}
+
+func TestConvertScriptsRejectsScriptAndCommand(t *testing.T) {
+	steps := []v1alpha1.BuildStep{
+		{Container: corev1.Container{Name: "both", Command: []string{"cmd"}}, Script: "echo hi"},
+	}
+	if _, _, err := convertScripts(steps); err == nil {
+		t.Error("convertScripts() = nil error, want an error for a step with both Script and Command")
+	}
+}
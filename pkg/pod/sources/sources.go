@@ -0,0 +1,97 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sources builds the container that fetches a Build's source,
+// for each of the source types a Build may specify.
+package sources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+)
+
+// Names of the containers produced by this package.
+const (
+	Git    = "git-source"
+	GCS    = "gcs-source"
+	Custom = "custom-source"
+)
+
+// validationError reports a Build source that isn't well-formed.
+type validationError struct {
+	Reason  string
+	Message string
+}
+
+func (ve *validationError) Error() string {
+	return ve.Reason + ": " + ve.Message
+}
+
+func newValidationError(reason, format string, fmtArgs ...interface{}) error {
+	return &validationError{Reason: reason, Message: fmt.Sprintf(format, fmtArgs...)}
+}
+
+// GitToContainer returns the init container that fetches the given git
+// source into the workspace.
+func GitToContainer(git *v1alpha1.GitSourceSpec, image, workspaceDir string, env []corev1.EnvVar, volumeMounts []corev1.VolumeMount) (*corev1.Container, error) {
+	if git.Url == "" {
+		return nil, newValidationError("MissingUrl", "git sources are expected to specify a Url, got: %v", git)
+	}
+	if git.Revision == "" {
+		return nil, newValidationError("MissingRevision", "git sources are expected to specify a Revision, got: %v", git)
+	}
+	return &corev1.Container{
+		Name:  Git,
+		Image: image,
+		Args: []string{
+			"-url", git.Url,
+			"-revision", git.Revision,
+		},
+		VolumeMounts: volumeMounts,
+		WorkingDir:   workspaceDir,
+		Env:          env,
+	}, nil
+}
+
+// GCSToContainer returns the init container that fetches the given GCS
+// source into the workspace.
+func GCSToContainer(gcs *v1alpha1.GCSSourceSpec, image, workspaceDir string, env []corev1.EnvVar, volumeMounts []corev1.VolumeMount) (*corev1.Container, error) {
+	if gcs.Location == "" {
+		return nil, newValidationError("MissingLocation", "gcs sources are expected to specify a Location, got: %v", gcs)
+	}
+	return &corev1.Container{
+		Name:         GCS,
+		Image:        image,
+		Args:         []string{"--type", string(gcs.Type), "--location", gcs.Location},
+		VolumeMounts: volumeMounts,
+		WorkingDir:   workspaceDir,
+		Env:          env,
+	}, nil
+}
+
+// CustomToContainer returns a copy of a custom source's container,
+// renamed to the custom source's reserved name.
+func CustomToContainer(source *corev1.Container) (*corev1.Container, error) {
+	if source.Name != "" {
+		return nil, newValidationError("OmitName", "custom source containers are expected to omit Name, got: %v", source.Name)
+	}
+	custom := source.DeepCopy()
+	custom.Name = Custom
+	return custom, nil
+}
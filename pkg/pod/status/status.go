@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package status derives a v1alpha1.BuildStatus from the Pod that
+// implements a Build.
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+	"github.com/knative/build/pkg/entrypoint"
+)
+
+// These mirror the naming convention pkg/pod uses when building the Pod;
+// they're duplicated here because pkg/pod doesn't export them.
+//
+// IMPORTANT: Changing these values without changing fluentd collection
+// configuration will break log collection for init containers.
+const (
+	initContainerPrefix        = "build-step-"
+	unnamedInitContainerPrefix = "build-step-unnamed-"
+)
+
+// implicitStepNames are containers injected by pkg/pod that aren't
+// user-authored build steps; their statuses are ignored when populating a
+// BuildStatus from a PodStatus.
+var implicitStepNames = map[string]bool{
+	initContainerPrefix + "credential-initializer": true,
+	initContainerPrefix + "gcs-source":             true,
+	initContainerPrefix + "git-source":             true,
+	initContainerPrefix + "custom-source":          true,
+	initContainerPrefix + "place-tools":            true,
+	initContainerPrefix + "stop-sidecars":          true,
+	initContainerPrefix + "place-scripts":          true,
+}
+
+// FromPod returns a BuildStatus based on the status of the given Pod.
+func FromPod(pod *corev1.Pod) (*v1alpha1.BuildStatus, error) {
+	status := &v1alpha1.BuildStatus{
+		Builder: v1alpha1.ClusterBuildProvider,
+		Cluster: &v1alpha1.ClusterSpec{
+			Namespace: pod.Namespace,
+			PodName:   pod.Name,
+		},
+	}
+
+	if pod.Status.StartTime != nil {
+		status.StartTime = *pod.Status.StartTime
+	}
+
+	// Steps run as regular containers (not init containers), so that they
+	// can all be started together and rely on the entrypoint binary to
+	// enforce ordering. Their statuses live in ContainerStatuses.
+	for _, cs := range pod.Status.ContainerStatuses {
+		// Ignore statuses for implicit steps added by pkg/pod (e.g., stop-sidecars).
+		if implicitStepNames[cs.Name] {
+			continue
+		}
+		// Sidecars keep their user-provided name rather than being
+		// prefixed into the step naming convention, so anything without
+		// that prefix is a sidecar, not a step.
+		if !strings.HasPrefix(cs.Name, initContainerPrefix) && !strings.HasPrefix(cs.Name, unnamedInitContainerPrefix) {
+			status.SidecarStates = append(status.SidecarStates, cs.State)
+			continue
+		}
+
+		state := cs.State
+		if term := state.Terminated; term != nil && strings.HasPrefix(term.Message, entrypoint.SkippedMessagePrefix) {
+			// A predecessor step failed, so the entrypoint never ran
+			// this step's command; reflect that distinctly rather than
+			// letting it appear as a normal success.
+			skipped := term.DeepCopy()
+			skipped.Reason = "Skipped"
+			skipped.Message = strings.TrimPrefix(term.Message, entrypoint.SkippedMessagePrefix)
+			state = corev1.ContainerState{Terminated: skipped}
+		} else if term != nil {
+			status.StepsCompleted = append(status.StepsCompleted, cs.Name)
+		}
+		status.StepStates = append(status.StepStates, state)
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		status.SetCondition(&duckv1alpha1.Condition{
+			Type:    v1alpha1.BuildSucceeded,
+			Status:  corev1.ConditionFalse,
+			Message: getFailureMessage(pod),
+		})
+	case corev1.PodPending:
+		status.SetCondition(&duckv1alpha1.Condition{
+			Type:    v1alpha1.BuildSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Message: "Pending",
+			Reason:  getWaitingMessage(pod),
+		})
+	case corev1.PodSucceeded:
+		status.SetCondition(&duckv1alpha1.Condition{
+			Type:   v1alpha1.BuildSucceeded,
+			Status: corev1.ConditionTrue,
+		})
+	default:
+		status.SetCondition(&duckv1alpha1.Condition{
+			Type:   v1alpha1.BuildSucceeded,
+			Status: corev1.ConditionUnknown,
+		})
+	}
+
+	return status, nil
+}
+
+func getWaitingMessage(pod *corev1.Pod) string {
+	// First, try to surface reason for pending/unknown about the actual build step.
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		wait := status.State.Waiting
+		if wait != nil && wait.Message != "" {
+			return fmt.Sprintf("build step %q is pending with reason %q",
+				status.Name, wait.Message)
+		}
+	}
+	// Try to surface underlying reason by inspecting pod's recent status if condition is not true
+	for i, podStatus := range pod.Status.Conditions {
+		if podStatus.Status != corev1.ConditionTrue {
+			return fmt.Sprintf("pod status %q:%q; message: %q",
+				pod.Status.Conditions[i].Type,
+				pod.Status.Conditions[i].Status,
+				pod.Status.Conditions[i].Message)
+		}
+	}
+	// Next, return the Pod's status message if it has one.
+	if pod.Status.Message != "" {
+		return pod.Status.Message
+	}
+
+	// Lastly fall back on a generic pending message.
+	return "Pending"
+}
+
+func getFailureMessage(pod *corev1.Pod) string {
+	// First, try to surface an error about the actual build step that failed.
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		term := status.State.Terminated
+		if term != nil && term.ExitCode != 0 {
+			return fmt.Sprintf("build step %q exited with code %d (image: %q); for logs run: kubectl -n %s logs %s -c %s",
+				status.Name, term.ExitCode, status.ImageID,
+				pod.Namespace, pod.Name, status.Name)
+		}
+	}
+	// Next, return the Pod's status message if it has one.
+	if pod.Status.Message != "" {
+		return pod.Status.Message
+	}
+	// Lastly fall back on a generic error message.
+	return "build failed for unspecified reasons."
+}
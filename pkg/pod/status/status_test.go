@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/knative/build/pkg/entrypoint"
+)
+
+func TestFromPodSkippedStep(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "build-step-one",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 0,
+					Message:  "the first step's failure reason",
+				}},
+			}, {
+				Name: "build-step-two",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 0,
+					Message:  entrypoint.SkippedMessagePrefix + "the first step's failure reason",
+				}},
+			}},
+		},
+	}
+
+	status, err := FromPod(pod)
+	if err != nil {
+		t.Fatalf("FromPod() = %v", err)
+	}
+
+	if got, want := len(status.StepsCompleted), 1; got != want {
+		t.Fatalf("len(StepsCompleted) = %d, want %d (skipped step shouldn't count)", got, want)
+	}
+	if got, want := status.StepsCompleted[0], "build-step-one"; got != want {
+		t.Errorf("StepsCompleted[0] = %q, want %q", got, want)
+	}
+
+	skipped := status.StepStates[1].Terminated
+	if skipped == nil {
+		t.Fatalf("StepStates[1].Terminated = nil, want non-nil")
+	}
+	if got, want := skipped.Reason, "Skipped"; got != want {
+		t.Errorf("skipped step Reason = %q, want %q", got, want)
+	}
+	if got, want := skipped.Message, "the first step's failure reason"; got != want {
+		t.Errorf("skipped step Message = %q, want %q", got, want)
+	}
+}
+
+func TestFromPodSeparatesSidecarsFromSteps(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "build-step-one",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+			}, {
+				Name:  "logger", // a sidecar keeps its user-provided name, unprefixed.
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+
+	status, err := FromPod(pod)
+	if err != nil {
+		t.Fatalf("FromPod() = %v", err)
+	}
+
+	if got, want := len(status.StepStates), 1; got != want {
+		t.Fatalf("len(StepStates) = %d, want %d (sidecar shouldn't count as a step)", got, want)
+	}
+	if got, want := len(status.SidecarStates), 1; got != want {
+		t.Fatalf("len(SidecarStates) = %d, want %d", got, want)
+	}
+	if status.SidecarStates[0].Running == nil {
+		t.Errorf("SidecarStates[0] = %v, want the sidecar's Running state", status.SidecarStates[0])
+	}
+	if got, want := len(status.StepsCompleted), 1; got != want {
+		t.Errorf("len(StepsCompleted) = %d, want %d (sidecar shouldn't count, even though it's Running not Terminated)", got, want)
+	}
+}